@@ -0,0 +1,114 @@
+package kallax
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// AggregateExpr is a SQL aggregate expression, such as COUNT(*) or
+// AVG(age). It can be selected alongside plain columns via
+// AppendSelectExpr, or used as the operand of a Having condition.
+type AggregateExpr struct {
+	fn  string
+	col string
+}
+
+// SQL returns the rendered aggregate expression, e.g. "COUNT(*)". It's
+// meant to be passed as the expr argument of AppendSelectExpr.
+func (a AggregateExpr) SQL() string {
+	return fmt.Sprintf("%s(%s)", a.fn, a.col)
+}
+
+// ToSql implements squirrel.Sqlizer, so an AggregateExpr can be used
+// anywhere a Sqlizer is expected, such as AppendSelectExpr's underlying
+// column list.
+func (a AggregateExpr) ToSql() (string, []interface{}, error) {
+	return a.SQL(), nil, nil
+}
+
+// Eq returns a Condition comparing the aggregate expression to value, for
+// use in Having.
+func (a AggregateExpr) Eq(value interface{}) Condition {
+	return a.cmp("=", value)
+}
+
+// Neq is the negated counterpart of Eq.
+func (a AggregateExpr) Neq(value interface{}) Condition {
+	return a.cmp("<>", value)
+}
+
+// Gt returns a Condition requiring the aggregate expression to be greater
+// than value, for use in Having.
+func (a AggregateExpr) Gt(value interface{}) Condition {
+	return a.cmp(">", value)
+}
+
+// Gte is the inclusive counterpart of Gt.
+func (a AggregateExpr) Gte(value interface{}) Condition {
+	return a.cmp(">=", value)
+}
+
+// Lt returns a Condition requiring the aggregate expression to be less
+// than value, for use in Having.
+func (a AggregateExpr) Lt(value interface{}) Condition {
+	return a.cmp("<", value)
+}
+
+// Lte is the inclusive counterpart of Lt.
+func (a AggregateExpr) Lte(value interface{}) Condition {
+	return a.cmp("<=", value)
+}
+
+func (a AggregateExpr) cmp(op string, value interface{}) Condition {
+	return aggCmp{expr: a, op: op, value: value}
+}
+
+// aggCmp is a Condition comparing an AggregateExpr against a bound value.
+type aggCmp struct {
+	expr  AggregateExpr
+	op    string
+	value interface{}
+}
+
+func (c aggCmp) ToSql() (string, []interface{}, error) {
+	return fmt.Sprintf("%s %s ?", c.expr.SQL(), c.op), []interface{}{c.value}, nil
+}
+
+// Count returns a COUNT(col) aggregate expression. Use "*" to count rows.
+func Count(col string) AggregateExpr {
+	return AggregateExpr{fn: "COUNT", col: col}
+}
+
+// Sum returns a SUM(col) aggregate expression.
+func Sum(col string) AggregateExpr {
+	return AggregateExpr{fn: "SUM", col: col}
+}
+
+// Avg returns an AVG(col) aggregate expression.
+func Avg(col string) AggregateExpr {
+	return AggregateExpr{fn: "AVG", col: col}
+}
+
+// Min returns a MIN(col) aggregate expression.
+func Min(col string) AggregateExpr {
+	return AggregateExpr{fn: "MIN", col: col}
+}
+
+// Max returns a MAX(col) aggregate expression.
+func Max(col string) AggregateExpr {
+	return AggregateExpr{fn: "MAX", col: col}
+}
+
+// GroupBy adds the given columns to the GROUP BY clause of the query.
+func (q *BaseQuery) GroupBy(cols ...string) {
+	q.builder = q.builder.GroupBy(cols...)
+}
+
+// Having adds a condition to be evaluated after GroupBy, typically built
+// from one of AggregateExpr's comparison methods:
+//   q.GroupBy("country")
+//   q.Having(Count("*").Gt(10))
+func (q *BaseQuery) Having(cond Condition) {
+	q.builder = q.builder.Having(squirrel.Sqlizer(cond))
+}
@@ -0,0 +1,71 @@
+package kallax
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+)
+
+func TestAggregateExprIsSqlizer(t *testing.T) {
+	var _ squirrel.Sqlizer = Count("*")
+}
+
+func TestGroupByHavingRollupQuery(t *testing.T) {
+	q := NewBaseQuery("users", "country")
+	q.GroupBy("country")
+	q.Having(Count("*").Gt(10))
+
+	_, builder, err := q.compile()
+	if err != nil {
+		t.Fatalf("compile: %s", err)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %s", err)
+	}
+
+	if !strings.Contains(sql, "GROUP BY country") {
+		t.Fatalf("expected GROUP BY country, got: %s", sql)
+	}
+	if !strings.Contains(sql, "HAVING COUNT(*) >") {
+		t.Fatalf("expected HAVING COUNT(*) > ..., got: %s", sql)
+	}
+	if len(args) != 1 || args[0] != 10 {
+		t.Fatalf("expected having arg [10], got %v", args)
+	}
+}
+
+func TestAggregateIntegratesWithWhereOrderLimit(t *testing.T) {
+	q := NewBaseQuery("users", "country")
+	q.Where(squirrel.Eq{"active": true})
+	q.GroupBy("country")
+	q.Having(Count("*").Gt(10))
+	q.Order(Desc("country"))
+	q.Limit(5)
+
+	_, builder, err := q.compile()
+	if err != nil {
+		t.Fatalf("compile: %s", err)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %s", err)
+	}
+
+	for _, want := range []string{"WHERE active", "GROUP BY country", "HAVING COUNT(*) >", "ORDER BY country DESC"} {
+		if !strings.Contains(sql, want) {
+			t.Fatalf("expected SQL to contain %q, got: %s", want, sql)
+		}
+	}
+
+	if len(args) != 2 || args[0] != true || args[1] != 10 {
+		t.Fatalf("expected args [true 10], got %v", args)
+	}
+
+	if q.GetLimit() != 5 {
+		t.Fatalf("expected GetLimit() == 5, got %d", q.GetLimit())
+	}
+}
@@ -0,0 +1,42 @@
+package kallax
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Condition is a boolean SQL expression used to filter rows (Where,
+// Having) or match tables (Join), such as the one built by ColEq/ColCmp
+// below. Any squirrel.Sqlizer satisfies it.
+type Condition interface {
+	squirrel.Sqlizer
+}
+
+// colCmp compares two columns directly, rather than a column against a
+// bound value.
+type colCmp struct {
+	left  string
+	op    string
+	right string
+}
+
+func (c colCmp) ToSql() (string, []interface{}, error) {
+	return fmt.Sprintf("%s %s %s", c.left, c.op, c.right), nil, nil
+}
+
+// ColEq returns a Condition comparing two columns for equality. Unlike a
+// value condition, neither side is bound as a parameter, which makes it
+// the right building block for a JOIN's ON clause or any other
+// cross-column comparison:
+//   q.Join("posts", "p", ColEq("p.user_id", "u.id"))
+func ColEq(left, right string) Condition {
+	return colCmp{left: left, op: "=", right: right}
+}
+
+// ColCmp returns a Condition comparing two columns with the given
+// operator (e.g. "=", "<>", ">", "<"), for the same cross-column cases as
+// ColEq.
+func ColCmp(left, op, right string) Condition {
+	return colCmp{left: left, op: op, right: right}
+}
@@ -0,0 +1,76 @@
+package kallax
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// cte is a single WITH clause bound to a query.
+type cte struct {
+	name      string
+	query     Query
+	recursive bool
+}
+
+// WithCTE prepends a `WITH name AS (...)` clause to the compiled query,
+// built by compiling sub. Once added, name can be referenced like a
+// regular table by subsequent Join, Where and Order calls, e.g.
+//   recent := NewBaseQuery("orders", "id", "user_id")
+//   recent.Where(Gt("created_at", since))
+//   q.WithCTE("recent_orders", recent)
+//   q.Join("recent_orders", "ro", ColEq("ro.user_id", "users.id"))
+func (q *BaseQuery) WithCTE(name string, sub Query) {
+	q.ctes = append(q.ctes, cte{name: name, query: sub})
+}
+
+// WithRecursive is like WithCTE but renders the WITH clause as `WITH
+// RECURSIVE`, for CTEs that reference their own name, such as recursive
+// tree walks (org charts, thread hierarchies).
+func (q *BaseQuery) WithRecursive(name string, sub Query) {
+	q.ctes = append(q.ctes, cte{name: name, query: sub, recursive: true})
+}
+
+// compileCTEs renders the accumulated WITH clauses as a single builder
+// prefix, merging their bind arguments ahead of the rest of the query's.
+//
+// Each CTE's sub-query is rendered with its placeholders left as "?"
+// rather than its own PlaceholderFormat, and the whole statement
+// (CTE prefix, columns, joins, where, ...) is only ever formatted once,
+// by the outer query's builder, when the caller finally calls ToSql(). If
+// we instead rendered each sub-query with its own Dollar-formatted
+// placeholders up front, the outer builder would renumber its *own*
+// placeholders from $1 again over the combined arg list, desyncing every
+// bind parameter that comes after a CTE with args.
+func (q *BaseQuery) compileCTEs() (string, []interface{}, error) {
+	if len(q.ctes) == 0 {
+		return "", nil, nil
+	}
+
+	var parts []string
+	var args []interface{}
+	recursive := false
+
+	for _, c := range q.ctes {
+		_, sub, err := c.query.compile()
+		if err != nil {
+			return "", nil, fmt.Errorf("kallax: cannot compile CTE %q: %s", c.name, err)
+		}
+
+		sql, subArgs, err := sub.PlaceholderFormat(squirrel.Question).ToSql()
+		if err != nil {
+			return "", nil, fmt.Errorf("kallax: cannot compile CTE %q: %s", c.name, err)
+		}
+
+		parts = append(parts, fmt.Sprintf("%s AS (%s)", c.name, sql))
+		args = append(args, subArgs...)
+		recursive = recursive || c.recursive
+	}
+
+	kw := "WITH"
+	if recursive {
+		kw = "WITH RECURSIVE"
+	}
+
+	return fmt.Sprintf("%s %s", kw, joinCols(parts)), args, nil
+}
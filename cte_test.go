@@ -0,0 +1,77 @@
+package kallax
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+)
+
+func TestCTEPlaceholdersDoNotCollide(t *testing.T) {
+	sub := NewBaseQuery("orders", "id", "user_id")
+	sub.Where(squirrel.Eq{"status": "shipped"})
+
+	q := NewBaseQuery("users", "id", "name")
+	q.WithCTE("shipped_orders", sub)
+	q.Where(squirrel.Eq{"active": true})
+
+	_, builder, err := q.compile()
+	if err != nil {
+		t.Fatalf("compile: %s", err)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %s", err)
+	}
+
+	if count := strings.Count(sql, "$1"); count != 1 {
+		t.Fatalf("expected exactly one $1 placeholder, got %d in: %s", count, sql)
+	}
+	if count := strings.Count(sql, "$2"); count != 1 {
+		t.Fatalf("expected exactly one $2 placeholder, got %d in: %s", count, sql)
+	}
+
+	if len(args) != 2 || args[0] != "shipped" || args[1] != true {
+		t.Fatalf("expected args [shipped true] in CTE-then-outer order, got %v", args)
+	}
+}
+
+func TestWithRecursiveRendersKeyword(t *testing.T) {
+	sub := NewBaseQuery("org_chart", "id", "manager_id")
+
+	q := NewBaseQuery("org_chart", "id")
+	q.WithRecursive("reports", sub)
+
+	sql := q.String()
+	if !strings.HasPrefix(sql, "WITH RECURSIVE reports AS (") {
+		t.Fatalf("expected WITH RECURSIVE prefix, got: %s", sql)
+	}
+}
+
+func TestCompileCTEErrorIsReturned(t *testing.T) {
+	q := NewBaseQuery("users", "id")
+	q.WithCTE("bad", failingQuery{})
+
+	if _, _, err := q.compile(); err == nil {
+		t.Fatal("expected compile to return an error for a failing CTE")
+	}
+}
+
+// failingQuery is a Query whose compile always fails, used to make sure
+// compileCTEs propagates errors instead of silently dropping the CTE.
+type failingQuery struct{}
+
+func (failingQuery) compile() ([]string, squirrel.SelectBuilder, error) {
+	return nil, squirrel.SelectBuilder{}, errFailingQuery
+}
+func (failingQuery) isReadOnly() bool     { return true }
+func (failingQuery) GetOffset() uint64    { return 0 }
+func (failingQuery) GetLimit() uint64     { return 0 }
+func (failingQuery) GetBatchSize() uint64 { return 0 }
+
+var errFailingQuery = &compileError{"boom"}
+
+type compileError struct{ msg string }
+
+func (e *compileError) Error() string { return e.msg }
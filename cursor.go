@@ -0,0 +1,242 @@
+package kallax
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// cursorSigningKey signs the opaque tokens returned by Cursor.Encode so that
+// a value round-tripped through a client (e.g. a "next page" query param)
+// can't be tampered with. Override it with SetCursorSigningKey at startup.
+var cursorSigningKey = []byte("kallax-default-cursor-key")
+
+// SetCursorSigningKey sets the HMAC key used to sign and verify pagination
+// cursors. It should be called once at startup with a secret specific to
+// the application; the default key is not safe for production use.
+func SetCursorSigningKey(key []byte) {
+	cursorSigningKey = key
+}
+
+// Cursor is the decoded form of an opaque seek (keyset) pagination token: a
+// set of ordering columns and the corresponding values of the last row seen
+// for each of them.
+type Cursor struct {
+	Columns []ColumnOrder
+	Values  []interface{}
+}
+
+type cursorPayload struct {
+	Cols   []string      `json:"cols"`
+	Values []interface{} `json:"values"`
+}
+
+// Encode returns the opaque, signed, base64-encoded representation of the
+// cursor.
+func (c *Cursor) Encode() (string, error) {
+	cols := make([]string, len(c.Columns))
+	for i, col := range c.Columns {
+		cols[i] = col.columnName()
+	}
+
+	payload, err := json.Marshal(cursorPayload{Cols: cols, Values: c.Values})
+	if err != nil {
+		return "", fmt.Errorf("kallax: cannot encode cursor: %s", err)
+	}
+
+	return base64.URLEncoding.EncodeToString(append(sign(payload), payload...)), nil
+}
+
+// DecodeCursor parses and verifies a token previously returned by
+// Cursor.Encode or BaseQuery.Cursor.
+func DecodeCursor(token string) (*Cursor, []string, error) {
+	raw, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("kallax: invalid cursor: %s", err)
+	}
+
+	if len(raw) < sha256.Size {
+		return nil, nil, errors.New("kallax: invalid cursor")
+	}
+
+	sig, payload := raw[:sha256.Size], raw[sha256.Size:]
+	if !hmac.Equal(sig, sign(payload)) {
+		return nil, nil, errors.New("kallax: cursor signature does not match, it may have been tampered with")
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, nil, fmt.Errorf("kallax: invalid cursor: %s", err)
+	}
+
+	return &Cursor{Values: p.Values}, p.Cols, nil
+}
+
+func sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, cursorSigningKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Cursor builds an opaque, signed pagination token for the given values of
+// the query's ordering columns (the ones passed to Order), typically taken
+// from the last row of a scanned page. No Store method calls it; the
+// caller passes the values, directly or through CursorFromRow.
+func (q *BaseQuery) Cursor(values ...interface{}) (string, error) {
+	if len(q.orderCols) == 0 {
+		return "", errors.New("kallax: Cursor requires Order to have been called on the query")
+	}
+	if len(values) != len(q.orderCols) {
+		return "", fmt.Errorf("kallax: expected %d values for the query's ordering columns, got %d", len(q.orderCols), len(values))
+	}
+
+	return (&Cursor{Columns: q.orderCols, Values: values}).Encode()
+}
+
+// CursorFromRow builds a pagination cursor from a scanned row, given as a
+// map of column name to value (e.g. one produced by Store.Aggregate, or a
+// hand-rolled scan of the last row of a page). It extracts the query's
+// ordering column values (set via Order), in order, then delegates to
+// Cursor. Store does not call it: the caller passes the last row of a page
+// to get a token for the next one.
+func (q *BaseQuery) CursorFromRow(row map[string]interface{}) (string, error) {
+	if len(q.orderCols) == 0 {
+		return "", errors.New("kallax: CursorFromRow requires Order to have been called on the query")
+	}
+
+	values := make([]interface{}, len(q.orderCols))
+	for i, col := range q.orderCols {
+		v, ok := row[col.columnName()]
+		if !ok {
+			return "", fmt.Errorf("kallax: row is missing ordering column %q", col.columnName())
+		}
+		values[i] = v
+	}
+
+	return q.Cursor(values...)
+}
+
+// ApplyCursor decodes a token previously returned by Cursor or
+// Cursor.Encode and applies it to the query as a seek (keyset) predicate
+// equivalent to After. The token is rejected if its ordering columns don't
+// match the query's current Order.
+func (q *BaseQuery) ApplyCursor(token string) error {
+	cursor, cols, err := DecodeCursor(token)
+	if err != nil {
+		return err
+	}
+
+	if len(q.orderCols) == 0 {
+		return errors.New("kallax: ApplyCursor requires Order to have been called on the query")
+	}
+
+	if len(cols) != len(q.orderCols) {
+		return errors.New("kallax: cursor does not match the query's ordering")
+	}
+	for i, col := range q.orderCols {
+		if col.columnName() != cols[i] {
+			return errors.New("kallax: cursor does not match the query's ordering")
+		}
+	}
+
+	cursor.Columns = q.orderCols
+	return q.After(cursor.Columns, cursor.Values...)
+}
+
+// After restricts the query to rows that come after the given ordering
+// columns' values, using a seek (keyset) predicate instead of OFFSET. cols
+// must match, in order and direction, the columns previously passed to
+// Order. For ascending columns (a, b, c) with non-nil values it generates:
+//   (a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+// with each "x > ?" widened to "(x > ? OR x IS NULL)", since NULLs sort
+// last in ascending order. The comparison flips per column for descending
+// order (where NULLs sort first), and a nil anchor value is handled with
+// IS NULL/IS NOT NULL.
+func (q *BaseQuery) After(cols []ColumnOrder, values ...interface{}) error {
+	return q.seek(cols, values, false)
+}
+
+// Before is the symmetric counterpart of After: it restricts the query to
+// rows that come before the given ordering columns' values.
+func (q *BaseQuery) Before(cols []ColumnOrder, values ...interface{}) error {
+	return q.seek(cols, values, true)
+}
+
+func (q *BaseQuery) seek(cols []ColumnOrder, values []interface{}, before bool) error {
+	if len(cols) == 0 {
+		return errors.New("kallax: After/Before require at least one ordering column")
+	}
+	if len(cols) != len(values) {
+		return fmt.Errorf("kallax: expected %d values for the seek columns, got %d", len(cols), len(values))
+	}
+	if len(q.orderCols) > 0 && !sameOrdering(q.orderCols, cols) {
+		return errors.New("kallax: seek columns do not match the query's Order")
+	}
+
+	branches := make([]squirrel.Sqlizer, len(cols))
+	for i := range cols {
+		and := make(squirrel.And, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, eqOrNull(cols[j].columnName(), values[j]))
+		}
+		and = append(and, seekCmp(cols[i], values[i], before))
+		branches[i] = and
+	}
+
+	q.builder = q.builder.Where(squirrel.Or(branches))
+	return nil
+}
+
+func sameOrdering(a, b []ColumnOrder) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ToSql() != b[i].ToSql() {
+			return false
+		}
+	}
+	return true
+}
+
+func eqOrNull(col string, value interface{}) squirrel.Sqlizer {
+	if value == nil {
+		return squirrel.Expr(col + " IS NULL")
+	}
+	return squirrel.Eq{col: value}
+}
+
+// seekCmp returns the boundary comparison for a single seek column,
+// following Postgres' default null ordering (NULLS LAST for ASC, NULLS
+// FIRST for DESC).
+func seekCmp(col ColumnOrder, value interface{}, before bool) squirrel.Sqlizer {
+	name := col.columnName()
+	after := !before
+	ascending := !col.isDesc()
+	nullsLast := ascending
+
+	if value == nil {
+		if after == nullsLast {
+			// NULL is the far end of the ordering: nothing lies past it.
+			return squirrel.Expr("1 = 0")
+		}
+		return squirrel.Expr(name + " IS NOT NULL")
+	}
+
+	op := ">"
+	if ascending != after {
+		op = "<"
+	}
+
+	cmp := squirrel.Expr(fmt.Sprintf("%s %s ?", name, op), value)
+	if after == nullsLast {
+		// NULLs sort past every non-NULL value in this direction.
+		return squirrel.Or{cmp, squirrel.Expr(name + " IS NULL")}
+	}
+	return cmp
+}
@@ -0,0 +1,72 @@
+package kallax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAfterGeneratesSeekPredicate(t *testing.T) {
+	q := NewBaseQuery("posts", "id", "created_at")
+	q.Order(Desc("created_at"), Asc("id"))
+
+	if err := q.After([]ColumnOrder{Desc("created_at"), Asc("id")}, "2024-01-01", 42); err != nil {
+		t.Fatalf("After: %s", err)
+	}
+
+	sql := q.String()
+	if !strings.Contains(sql, "created_at <") {
+		t.Fatalf("expected a descending seek predicate on created_at, got: %s", sql)
+	}
+	if !strings.Contains(sql, "id >") {
+		t.Fatalf("expected an ascending tie-break predicate on id, got: %s", sql)
+	}
+}
+
+func TestAfterRejectsMismatchedColumnCount(t *testing.T) {
+	q := NewBaseQuery("posts", "id")
+	q.Order(Asc("id"))
+
+	if err := q.After([]ColumnOrder{Asc("id")}, 1, 2); err == nil {
+		t.Fatal("expected After to reject a values count that doesn't match the columns")
+	}
+}
+
+func TestAfterRejectsOrderingThatDoesNotMatchOrder(t *testing.T) {
+	q := NewBaseQuery("posts", "id", "created_at")
+	q.Order(Asc("id"))
+
+	if err := q.After([]ColumnOrder{Desc("created_at")}, "2024-01-01"); err == nil {
+		t.Fatal("expected After to reject seek columns that don't match Order")
+	}
+}
+
+func TestCursorFromRowAndApplyCursor(t *testing.T) {
+	q := NewBaseQuery("posts", "id", "created_at")
+	q.Order(Desc("created_at"), Asc("id"))
+
+	token, err := q.CursorFromRow(map[string]interface{}{"created_at": "2024-01-01", "id": 42})
+	if err != nil {
+		t.Fatalf("CursorFromRow: %s", err)
+	}
+
+	q2 := NewBaseQuery("posts", "id", "created_at")
+	q2.Order(Desc("created_at"), Asc("id"))
+	if err := q2.ApplyCursor(token); err != nil {
+		t.Fatalf("ApplyCursor: %s", err)
+	}
+
+	q3 := NewBaseQuery("posts", "id", "created_at")
+	q3.Order(Asc("created_at"))
+	if err := q3.ApplyCursor(token); err == nil {
+		t.Fatal("expected ApplyCursor to reject a cursor whose ordering doesn't match the query")
+	}
+}
+
+func TestCursorFromRowMissingColumn(t *testing.T) {
+	q := NewBaseQuery("posts", "id", "created_at")
+	q.Order(Asc("id"))
+
+	if _, err := q.CursorFromRow(map[string]interface{}{"created_at": "2024-01-01"}); err == nil {
+		t.Fatal("expected CursorFromRow to fail when the row is missing an ordering column")
+	}
+}
@@ -0,0 +1,178 @@
+package kallax
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// defaultPlaceholder is the squirrel.PlaceholderFormat used by
+// NewBaseQuery. It defaults to squirrel.Dollar (PostgreSQL); override it
+// with SetDefaultPlaceholder for applications that only ever talk to a
+// different backend.
+var defaultPlaceholder squirrel.PlaceholderFormat = squirrel.Dollar
+
+// SetDefaultPlaceholder overrides the placeholder format used by
+// NewBaseQuery. Call it once at startup, before any query is built.
+func SetDefaultPlaceholder(format squirrel.PlaceholderFormat) {
+	defaultPlaceholder = format
+}
+
+// Dialect describes the SQL backend a BaseQuery targets: its bind
+// placeholder style, identifier quoting, and support for features that
+// vary across backends, such as RETURNING and upserts.
+type Dialect struct {
+	// Name identifies the dialect, e.g. "postgres", "mysql", "sqlite3".
+	Name string
+	// Placeholder is the bind variable format, e.g. squirrel.Dollar for
+	// Postgres or squirrel.Question for MySQL/SQLite.
+	Placeholder squirrel.PlaceholderFormat
+	// QuoteIdent wraps an identifier in this dialect's quoting characters.
+	QuoteIdent func(ident string) string
+	// SupportsReturning reports whether INSERT/UPDATE ... RETURNING is
+	// available.
+	SupportsReturning bool
+	// Upsert renders the conflict-handling clause of an upsert for this
+	// dialect, given the conflicting columns and the columns to update on
+	// conflict.
+	Upsert func(conflictCols, updateCols []string) string
+}
+
+// Postgres is the Dialect kallax targets by default.
+var Postgres = Dialect{
+	Name:              "postgres",
+	Placeholder:       squirrel.Dollar,
+	QuoteIdent:        doubleQuoteIdent,
+	SupportsReturning: true,
+	Upsert:            conflictUpsert,
+}
+
+// MySQL targets MySQL/MariaDB: "?" placeholders, backtick-quoted
+// identifiers, no RETURNING support, and ON DUPLICATE KEY UPDATE upserts.
+var MySQL = Dialect{
+	Name:              "mysql",
+	Placeholder:       squirrel.Question,
+	QuoteIdent:        backtickQuoteIdent,
+	SupportsReturning: false,
+	Upsert: func(conflictCols, updateCols []string) string {
+		return "ON DUPLICATE KEY UPDATE " + setClauseValues(updateCols)
+	},
+}
+
+// SQLite targets SQLite: "?" placeholders, double-quoted identifiers, no
+// RETURNING support, and Postgres-style upserts (supported since SQLite
+// 3.24).
+var SQLite = Dialect{
+	Name:              "sqlite3",
+	Placeholder:       squirrel.Question,
+	QuoteIdent:        doubleQuoteIdent,
+	SupportsReturning: false,
+	Upsert:            conflictUpsert,
+}
+
+func conflictUpsert(conflictCols, updateCols []string) string {
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", joinCols(conflictCols), setClauseExcluded(updateCols))
+}
+
+// setClauseExcluded renders "col = EXCLUDED.col, ..." for the Postgres and
+// SQLite ON CONFLICT ... DO UPDATE syntax.
+func setClauseExcluded(cols []string) string {
+	return setClause(cols, func(c string) string { return "EXCLUDED." + c })
+}
+
+// setClauseValues renders "col = VALUES(col), ..." for the MySQL ON
+// DUPLICATE KEY UPDATE syntax.
+func setClauseValues(cols []string) string {
+	return setClause(cols, func(c string) string { return fmt.Sprintf("VALUES(%s)", c) })
+}
+
+func setClause(cols []string, newValue func(col string) string) string {
+	parts := make([]string, len(cols))
+	for i, c := range cols {
+		parts[i] = fmt.Sprintf("%s = %s", c, newValue(c))
+	}
+	return joinCols(parts)
+}
+
+func doubleQuoteIdent(ident string) string {
+	return `"` + ident + `"`
+}
+
+func backtickQuoteIdent(ident string) string {
+	return "`" + ident + "`"
+}
+
+// NewBaseQueryWithDialect is like NewBaseQuery but targets the given
+// Dialect instead of the package default, for querying non-PostgreSQL
+// backends such as MySQL or SQLite.
+func NewBaseQueryWithDialect(dialect Dialect, table string, selectedColumns ...string) *BaseQuery {
+	q := NewBaseQuery(table, selectedColumns...)
+	q.dialect = dialect
+	q.builder = q.builder.PlaceholderFormat(dialect.Placeholder)
+	return q
+}
+
+// GetDialect returns the Dialect the query was built with (Postgres
+// unless set via NewBaseQueryWithDialect). Store has no insert or update
+// path that reads it; Dialect.InsertBuilder and Dialect.UpdateBuilder
+// build those statements for a given dialect.
+func (q *BaseQuery) GetDialect() Dialect {
+	return q.dialect
+}
+
+// InsertBuilder returns a squirrel.InsertBuilder for table, using this
+// dialect's placeholder format and identifier quoting. If
+// conflictCols is non-empty, the dialect's upsert clause is appended as a
+// suffix (ON CONFLICT ... DO UPDATE for Postgres/SQLite, ON DUPLICATE KEY
+// UPDATE for MySQL); otherwise, RETURNING * is appended when the dialect
+// supports it.
+func (d Dialect) InsertBuilder(table string, cols []string, values []interface{}, conflictCols []string) squirrel.InsertBuilder {
+	quotedCols := make([]string, len(cols))
+	for i, c := range cols {
+		quotedCols[i] = d.QuoteIdent(c)
+	}
+
+	b := squirrel.StatementBuilder.
+		PlaceholderFormat(d.Placeholder).
+		Insert(d.QuoteIdent(table)).
+		Columns(quotedCols...).
+		Values(values...)
+
+	if len(conflictCols) > 0 {
+		quotedConflict := make([]string, len(conflictCols))
+		for i, c := range conflictCols {
+			quotedConflict[i] = d.QuoteIdent(c)
+		}
+
+		isConflictCol := make(map[string]bool, len(quotedConflict))
+		for _, c := range quotedConflict {
+			isConflictCol[c] = true
+		}
+		var updateCols []string
+		for _, c := range quotedCols {
+			if !isConflictCol[c] {
+				updateCols = append(updateCols, c)
+			}
+		}
+
+		b = b.Suffix(d.Upsert(quotedConflict, updateCols))
+	} else if d.SupportsReturning {
+		b = b.Suffix("RETURNING *")
+	}
+
+	return b
+}
+
+// UpdateBuilder returns a squirrel.UpdateBuilder for table, using this
+// dialect's placeholder format, identifier quoting, and RETURNING support.
+func (d Dialect) UpdateBuilder(table string) squirrel.UpdateBuilder {
+	b := squirrel.StatementBuilder.
+		PlaceholderFormat(d.Placeholder).
+		Update(d.QuoteIdent(table))
+
+	if d.SupportsReturning {
+		b = b.Suffix("RETURNING *")
+	}
+
+	return b
+}
@@ -0,0 +1,68 @@
+package kallax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostgresUpsertClause(t *testing.T) {
+	sql, args, err := Postgres.
+		InsertBuilder("users", []string{"id", "email"}, []interface{}{1, "a@b.com"}, []string{"id"}).
+		ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %s", err)
+	}
+
+	if !strings.Contains(sql, `ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email"`) {
+		t.Fatalf("unexpected postgres upsert clause: %s", sql)
+	}
+	if !strings.Contains(sql, "$1") {
+		t.Fatalf("expected postgres to use $-numbered placeholders, got: %s", sql)
+	}
+	if len(args) != 2 {
+		t.Fatalf("expected 2 bind args, got %v", args)
+	}
+}
+
+func TestMySQLUpsertClause(t *testing.T) {
+	sql, _, err := MySQL.
+		InsertBuilder("users", []string{"id", "email"}, []interface{}{1, "a@b.com"}, []string{"id"}).
+		ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %s", err)
+	}
+
+	if !strings.Contains(sql, "ON DUPLICATE KEY UPDATE `email` = VALUES(`email`)") {
+		t.Fatalf("unexpected mysql upsert clause: %s", sql)
+	}
+	if strings.Contains(sql, "$1") {
+		t.Fatalf("mysql should use ? placeholders, not $-numbered ones, got: %s", sql)
+	}
+	if strings.Contains(sql, "RETURNING") {
+		t.Fatalf("mysql does not support RETURNING, got: %s", sql)
+	}
+}
+
+func TestSQLiteInsertHasNoReturning(t *testing.T) {
+	sql, _, err := SQLite.
+		InsertBuilder("users", []string{"id", "email"}, []interface{}{1, "a@b.com"}, nil).
+		ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %s", err)
+	}
+
+	if strings.Contains(sql, "RETURNING") {
+		t.Fatalf("sqlite does not support RETURNING, got: %s", sql)
+	}
+}
+
+func TestDialectUpdateBuilderUsesReturning(t *testing.T) {
+	sql, _, err := Postgres.UpdateBuilder("users").Set("email", "a@b.com").ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %s", err)
+	}
+
+	if !strings.Contains(sql, "RETURNING *") {
+		t.Fatalf("expected postgres UPDATE to request RETURNING *, got: %s", sql)
+	}
+}
@@ -0,0 +1,75 @@
+package kallax
+
+import "fmt"
+
+// joinType is the SQL keyword used to introduce a JOIN clause.
+type joinType string
+
+const (
+	innerJoinType joinType = "JOIN"
+	leftJoinType  joinType = "LEFT JOIN"
+	rightJoinType joinType = "RIGHT JOIN"
+)
+
+// joinClause renders a single JOIN clause of a query, including its ON
+// condition.
+type joinClause struct {
+	kind  joinType
+	table string
+	alias string
+	cond  Condition
+}
+
+func (j joinClause) ToSql() (string, []interface{}, error) {
+	condSQL, args, err := j.cond.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	target := j.table
+	if j.alias != "" {
+		target = fmt.Sprintf("%s AS %s", j.table, j.alias)
+	}
+
+	return fmt.Sprintf("%s %s ON %s", j.kind, target, condSQL), args, nil
+}
+
+// Join adds an INNER JOIN against the given table (or another kallax
+// model's Schema.Table()) under the given alias, matched by cond. Use
+// ColEq/ColCmp for the FK condition, since Eq/Gt/... bind their right-hand
+// side as a parameter and can't compare one column to another. Columns on
+// the joined table can then be referenced, qualified with alias, by the
+// rest of the query, e.g.
+//   q.Join(PostSchema.Table(), "p", ColEq("p.user_id", "u.id"))
+//   q.Where(Eq("p.title", "hello"))
+//   q.Order(Asc("p.created_at"))
+//
+// This is the hand-written join primitive, not the real feature: a
+// generated model query would expose e.g. UserQuery.JoinPosts(), reading
+// the FK column and target table from schema metadata so callers never
+// write ColEq themselves. No generator ships in this tree, so callers
+// build the ON condition explicitly as shown above.
+func (q *BaseQuery) Join(table, alias string, cond Condition) {
+	q.join(innerJoinType, table, alias, cond)
+}
+
+// InnerJoin is an alias of Join.
+func (q *BaseQuery) InnerJoin(table, alias string, cond Condition) {
+	q.join(innerJoinType, table, alias, cond)
+}
+
+// LeftJoin adds a LEFT JOIN against the given table under the given alias,
+// matched by cond.
+func (q *BaseQuery) LeftJoin(table, alias string, cond Condition) {
+	q.join(leftJoinType, table, alias, cond)
+}
+
+// RightJoin adds a RIGHT JOIN against the given table under the given
+// alias, matched by cond.
+func (q *BaseQuery) RightJoin(table, alias string, cond Condition) {
+	q.join(rightJoinType, table, alias, cond)
+}
+
+func (q *BaseQuery) join(kind joinType, table, alias string, cond Condition) {
+	q.builder = q.builder.JoinClause(joinClause{kind: kind, table: table, alias: alias, cond: cond})
+}
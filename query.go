@@ -8,7 +8,7 @@ import (
 
 // Query returns information about some query settings and compiles the query.
 type Query interface {
-	compile() ([]string, squirrel.SelectBuilder)
+	compile() ([]string, squirrel.SelectBuilder, error)
 	isReadOnly() bool
 	// GetOffset returns the number of skipped rows in the query.
 	GetOffset() uint64
@@ -66,7 +66,11 @@ func (cs columnSet) copy() []string {
 type BaseQuery struct {
 	columns         columnSet
 	excludedColumns columnSet
+	exprColumns     []exprColumn
+	orderCols       []ColumnOrder
+	ctes            []cte
 	builder         squirrel.SelectBuilder
+	dialect         Dialect
 
 	selectChanged bool
 	batchSize     uint64
@@ -81,11 +85,12 @@ var _ Query = (*BaseQuery)(nil)
 func NewBaseQuery(table string, selectedColumns ...string) *BaseQuery {
 	return &BaseQuery{
 		builder: squirrel.StatementBuilder.
-			PlaceholderFormat(squirrel.Dollar).
+			PlaceholderFormat(defaultPlaceholder).
 			Select().
 			From(table),
 		columns:   columnSet(selectedColumns),
 		batchSize: 50,
+		dialect:   Postgres,
 	}
 }
 
@@ -116,6 +121,10 @@ func (q *BaseQuery) Copy() *BaseQuery {
 		builder:         q.builder,
 		columns:         q.columns.copy(),
 		excludedColumns: q.excludedColumns.copy(),
+		exprColumns:     append([]exprColumn(nil), q.exprColumns...),
+		orderCols:       append([]ColumnOrder(nil), q.orderCols...),
+		ctes:            append([]cte(nil), q.ctes...),
+		dialect:         q.dialect,
 		selectChanged:   q.selectChanged,
 		batchSize:       q.GetBatchSize(),
 		limit:           q.GetLimit(),
@@ -141,6 +150,14 @@ func (q *BaseQuery) Order(cols ...ColumnOrder) {
 		c[i] = v.ToSql()
 	}
 	q.builder = q.builder.OrderBy(c...)
+	q.orderCols = append(q.orderCols, cols...)
+}
+
+// GetOrder returns the columns the query is ordered by, in the order they
+// were passed to Order: the same ordering that Cursor, CursorFromRow and
+// ApplyCursor work from.
+func (q *BaseQuery) GetOrder() []ColumnOrder {
+	return q.orderCols
 }
 
 // BatchSize sets the batch size.
@@ -184,14 +201,35 @@ func (q *BaseQuery) Where(cond Condition) {
 }
 
 // compile returns the selected column names and the select builder.
-func (q *BaseQuery) compile() ([]string, squirrel.SelectBuilder) {
+func (q *BaseQuery) compile() ([]string, squirrel.SelectBuilder, error) {
 	columns := q.selectedColumns()
-	return columns, q.builder.Columns(columns...)
+	builder := q.builder.Columns(columns...)
+
+	for _, c := range q.exprColumns {
+		builder = builder.Column(
+			squirrel.Alias(squirrel.Expr(c.sql, c.args...), c.alias),
+		)
+		columns = append(columns, c.alias)
+	}
+
+	prefix, args, err := q.compileCTEs()
+	if err != nil {
+		return nil, builder, err
+	}
+	if prefix != "" {
+		builder = builder.Prefix(prefix, args...)
+	}
+
+	return columns, builder, nil
 }
 
-// String returns the SQL generated by the
+// String returns the SQL generated by the query, or an empty string if it
+// fails to compile.
 func (q *BaseQuery) String() string {
-	_, builder := q.compile()
+	_, builder, err := q.compile()
+	if err != nil {
+		return ""
+	}
 	sql, _, _ := builder.ToSql()
 	return sql
 }
@@ -201,6 +239,10 @@ type ColumnOrder interface {
 	// ToSql returns the SQL representation of the column with its order.
 	ToSql() string
 	isColumnOrder()
+	// columnName returns the bare column name, without the order.
+	columnName() string
+	// isDesc reports whether the column is ordered descending.
+	isDesc() bool
 }
 
 type colOrder struct {
@@ -213,6 +255,14 @@ func (o *colOrder) ToSql() string {
 }
 func (colOrder) isColumnOrder() {}
 
+func (o *colOrder) columnName() string {
+	return o.col
+}
+
+func (o *colOrder) isDesc() bool {
+	return o.order == desc
+}
+
 const (
 	asc  = "ASC"
 	desc = "DESC"
@@ -0,0 +1,39 @@
+package kallax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWindowOver(t *testing.T) {
+	clause := WindowOver(
+		[]ColumnOrder{Asc("user_id")},
+		[]ColumnOrder{Desc("created_at")},
+	)
+
+	if clause != "PARTITION BY user_id ORDER BY created_at DESC" {
+		t.Fatalf("unexpected window clause: %s", clause)
+	}
+}
+
+func TestAppendSelectExprAddsAliasedColumn(t *testing.T) {
+	over := WindowOver(
+		[]ColumnOrder{Asc("user_id")},
+		[]ColumnOrder{Desc("created_at")},
+	)
+
+	q := NewBaseQuery("posts", "id")
+	q.AppendSelectExpr("ROW_NUMBER() OVER ("+over+")", "rn")
+
+	sql := q.String()
+	if !strings.Contains(sql, "ROW_NUMBER() OVER") {
+		t.Fatalf("expected the window function in the selected columns, got: %s", sql)
+	}
+	if !strings.Contains(sql, "AS rn") {
+		t.Fatalf("expected the expression aliased as rn, got: %s", sql)
+	}
+
+	if aliases := q.SelectExprAliases(); len(aliases) != 1 || aliases[0] != "rn" {
+		t.Fatalf("expected SelectExprAliases to report [rn], got %v", aliases)
+	}
+}
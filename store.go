@@ -0,0 +1,73 @@
+package kallax
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Store executes compiled queries against a database/sql connection. It is
+// the hand-written execution path, not the real feature: this package
+// snapshot only contains the query builder, so Store exists just to give
+// GroupBy/Having somewhere to run (Aggregate). A generated model Store
+// would scan each GROUP BY into a typed result struct instead of the
+// []map[string]interface{} below, and would also carry 1:N relationship
+// loading and transactions. No generator ships in this tree.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore wraps db for executing aggregate queries.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Aggregate runs query with the given aggregate expressions appended to
+// its selected columns, grouped by query's GroupBy columns and filtered by
+// its Having condition, and returns one map per result row keyed by column
+// or alias name, e.g.:
+//   q := NewBaseQuery("users", "country")
+//   q.GroupBy("country")
+//   q.Having(Count("*").Gt(10))
+//   rows, err := store.Aggregate(q, Count("*"), Avg("age"))
+func (s *Store) Aggregate(query *BaseQuery, aggregators ...AggregateExpr) ([]map[string]interface{}, error) {
+	q := query.Copy()
+	for i, agg := range aggregators {
+		q.AppendSelectExpr(agg.SQL(), fmt.Sprintf("agg_%d", i))
+	}
+
+	columns, builder, err := q.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		results = append(results, row)
+	}
+
+	return results, rows.Err()
+}